@@ -3,8 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
+	"time"
 
+	"github.com/alibaba/pouch/apis/types"
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +22,38 @@ var createDescription = "Create a static container object in Pouchd. " +
 type CreateCommand struct {
 	container
 	baseCommand
+
+	// cpus is the number of CPUs made available to the container, expressed
+	// as a fractional value (e.g. 1.5). When set, it is translated into a
+	// cpuPeriod/cpuQuota pair; combining it with an explicit cpuPeriod or
+	// cpuQuota is an error.
+	cpus         float64
+	cpuPeriod    int64
+	cpuQuota     int64
+	cpuRTPeriod  int64
+	cpuRTRuntime int64
+
+	ulimits ulimitOpt
+	tmpfs   tmpfsOpt
+	mounts  mountOpt
+
+	oomScoreAdj    int
+	oomKillDisable bool
+
+	healthCmd         string
+	healthInterval    time.Duration
+	healthTimeout     time.Duration
+	healthStartPeriod time.Duration
+	healthRetries     int
+	noHealthcheck     bool
+
+	dns           []string
+	dnsSearch     []string
+	dnsOptions    []string
+	extraHosts    []string
+	hostnameAlias string
+
+	envFiles []string
 }
 
 // Init initialize create command.
@@ -45,6 +81,7 @@ func (cc *CreateCommand) addFlags() {
 	flagSet.StringSliceVarP(&cc.volume, "volume", "v", nil, "Bind mount volumes to container")
 	flagSet.StringVar(&cc.runtime, "runtime", "", "Specify oci runtime")
 	flagSet.StringSliceVarP(&cc.env, "env", "e", nil, "Set environment variables for container")
+	flagSet.StringSliceVar(&cc.envFiles, "env-file", nil, "Read in a file of environment variables")
 	flagSet.StringSliceVarP(&cc.labels, "label", "l", nil, "Set label for a container")
 	flagSet.StringVar(&cc.entrypoint, "entrypoint", "", "Overwrite the default entrypoint")
 	flagSet.StringVarP(&cc.workdir, "workdir", "w", "", "Set the working directory in a container")
@@ -55,6 +92,31 @@ func (cc *CreateCommand) addFlags() {
 	flagSet.Int64Var(&cc.cpushare, "cpu-share", 0, "CPU shares")
 	flagSet.StringVar(&cc.cpusetcpus, "cpuset-cpus", "", "CPUs in cpuset")
 	flagSet.StringVar(&cc.cpusetmems, "cpuset-mems", "", "MEMs in cpuset")
+	flagSet.Float64Var(&cc.cpus, "cpus", 0, "Number of CPUs")
+	flagSet.Int64Var(&cc.cpuPeriod, "cpu-period", 0, "Limit CPU CFS (Completely Fair Scheduler) period")
+	flagSet.Int64Var(&cc.cpuQuota, "cpu-quota", 0, "Limit CPU CFS (Completely Fair Scheduler) quota")
+	flagSet.Int64Var(&cc.cpuRTPeriod, "cpu-rt-period", 0, "Limit CPU real-time period in microseconds")
+	flagSet.Int64Var(&cc.cpuRTRuntime, "cpu-rt-runtime", 0, "Limit CPU real-time runtime in microseconds")
+	flagSet.Var(&cc.ulimits, "ulimit", "Ulimit options (default [])")
+	flagSet.Var(&cc.tmpfs, "tmpfs", "Mount a tmpfs directory")
+	flagSet.Var(&cc.mounts, "mount", "Attach a filesystem mount to the container")
+	flagSet.IntVar(&cc.oomScoreAdj, "oom-score-adj", 0, "Tune host's OOM preferences (-1000 to 1000)")
+	flagSet.BoolVar(&cc.oomKillDisable, "oom-kill-disable", false, "Whether to disable OOM Killer for the container")
+
+	// healthcheck
+	flagSet.StringVar(&cc.healthCmd, "health-cmd", "", "Command to run to check health")
+	flagSet.DurationVar(&cc.healthInterval, "health-interval", 0, "Time between running the check")
+	flagSet.DurationVar(&cc.healthTimeout, "health-timeout", 0, "Maximum time to allow one check to run")
+	flagSet.DurationVar(&cc.healthStartPeriod, "health-start-period", 0, "Start period for the container to initialize before starting health-retries countdown")
+	flagSet.IntVar(&cc.healthRetries, "health-retries", 0, "Consecutive failures needed to report unhealthy")
+	flagSet.BoolVar(&cc.noHealthcheck, "no-healthcheck", false, "Disable any container-specified healthcheck")
+
+	// network
+	flagSet.StringSliceVar(&cc.dns, "dns", nil, "Set custom DNS servers")
+	flagSet.StringSliceVar(&cc.dnsSearch, "dns-search", nil, "Set custom DNS search domains")
+	flagSet.StringSliceVar(&cc.dnsOptions, "dns-option", nil, "Set DNS options")
+	flagSet.StringSliceVar(&cc.extraHosts, "add-host", nil, "Add a custom host-to-IP mapping (host:ip)")
+	flagSet.StringVar(&cc.hostnameAlias, "hostname-alias", "", "Set an alias for container's hostname")
 
 	// memory
 	flagSet.Int64Var(&cc.memorySwappiness, "memory-wappiness", -1, "Container memory swappiness [0, 100]")
@@ -98,11 +160,80 @@ func (cc *CreateCommand) addFlags() {
 
 // runCreate is the entry of create command.
 func (cc *CreateCommand) runCreate(args []string) error {
+	if cc.oomScoreAdj < -1000 || cc.oomScoreAdj > 1000 {
+		return fmt.Errorf("failed to create container: invalid value for --oom-score-adj: %d, must be between -1000 and 1000", cc.oomScoreAdj)
+	}
+	if cc.oomKillDisable && isMemoryUnbounded(cc.memory) {
+		fmt.Println("WARNING: Disabling the OOM killer on a container without a memory limit may be dangerous")
+	}
+
+	if err := cc.validateHealthFlags(); err != nil {
+		return fmt.Errorf("failed to create container: %v", err)
+	}
+
+	if err := cc.validateNetworkFlags(); err != nil {
+		return fmt.Errorf("failed to create container: %v", err)
+	}
+
+	env, err := mergeEnvFiles(cc.envFiles, cc.env)
+	if err != nil {
+		return fmt.Errorf("failed to create container: %v", err)
+	}
+	cc.env = env
+
+	cpuPeriod, cpuQuota, err := cc.resolveCPUQuota()
+	if err != nil {
+		return fmt.Errorf("failed to create container: %v", err)
+	}
+
 	config, err := cc.config()
 	if err != nil {
 		return fmt.Errorf("failed to create container: %v", err)
 	}
 
+	config.HostConfig.CPUPeriod = cpuPeriod
+	config.HostConfig.CPUQuota = cpuQuota
+	config.HostConfig.CPURealtimePeriod = cc.cpuRTPeriod
+	config.HostConfig.CPURealtimeRuntime = cc.cpuRTRuntime
+	config.HostConfig.Ulimits = cc.ulimits.values
+	config.HostConfig.Tmpfs = cc.tmpfs.values
+
+	if err := validateMountTargets(cc.volume, cc.mounts.values); err != nil {
+		return fmt.Errorf("failed to create container: %v", err)
+	}
+	apiMounts, err := toAPIMounts(cc.mounts.values)
+	if err != nil {
+		return fmt.Errorf("failed to create container: %v", err)
+	}
+	config.HostConfig.Mounts = apiMounts
+
+	config.HostConfig.OomScoreAdj = int64(cc.oomScoreAdj)
+	config.HostConfig.OomKillDisable = cc.oomKillDisable
+
+	if cc.noHealthcheck {
+		if config.ContainerConfig.Healthcheck == nil {
+			config.ContainerConfig.Healthcheck = &types.HealthConfig{}
+		}
+		config.ContainerConfig.Healthcheck.Test = []string{"NONE"}
+	} else if cc.healthCmd != "" || cc.healthInterval != 0 || cc.healthTimeout != 0 || cc.healthStartPeriod != 0 || cc.healthRetries != 0 {
+		if config.ContainerConfig.Healthcheck == nil {
+			config.ContainerConfig.Healthcheck = &types.HealthConfig{}
+		}
+		if cc.healthCmd != "" {
+			config.ContainerConfig.Healthcheck.Test = []string{"CMD-SHELL", cc.healthCmd}
+		}
+		config.ContainerConfig.Healthcheck.Interval = cc.healthInterval
+		config.ContainerConfig.Healthcheck.Timeout = cc.healthTimeout
+		config.ContainerConfig.Healthcheck.StartPeriod = cc.healthStartPeriod
+		config.ContainerConfig.Healthcheck.Retries = cc.healthRetries
+	}
+
+	config.HostConfig.DNS = cc.dns
+	config.HostConfig.DNSSearch = cc.dnsSearch
+	config.HostConfig.DNSOptions = cc.dnsOptions
+	config.HostConfig.ExtraHosts = cc.extraHosts
+	config.HostConfig.HostnameAlias = cc.hostnameAlias
+
 	config.Image = args[0]
 	if len(args) > 1 {
 		config.Cmd = args[1:]
@@ -123,6 +254,120 @@ func (cc *CreateCommand) runCreate(args []string) error {
 	return nil
 }
 
+// isMemoryUnbounded reports whether memory, as passed to --memory, leaves
+// the container without a memory limit: either unset or an explicit zero.
+func isMemoryUnbounded(memory string) bool {
+	if memory == "" {
+		return true
+	}
+	bytes, err := units.RAMInBytes(memory)
+	return err == nil && bytes == 0
+}
+
+// resolveCPUQuota reconciles --cpus with --cpu-period/--cpu-quota. --cpus is
+// translated into a period=100000us, quota=cpus*100000us pair unless the
+// caller has already set the period or quota explicitly, in which case the
+// two forms of input are rejected as conflicting.
+func (cc *CreateCommand) resolveCPUQuota() (int64, int64, error) {
+	if cc.cpus == 0 {
+		if cc.cpuQuota != 0 && cc.cpuQuota < 1000 {
+			return 0, 0, fmt.Errorf("invalid value for --cpu-quota: %dus is below the 1000us minimum", cc.cpuQuota)
+		}
+		return cc.cpuPeriod, cc.cpuQuota, nil
+	}
+	if cc.cpus < 0 {
+		return 0, 0, fmt.Errorf("invalid value for --cpus: %v, must be greater than 0", cc.cpus)
+	}
+	if cc.cpuPeriod != 0 || cc.cpuQuota != 0 {
+		return 0, 0, fmt.Errorf("--cpus cannot be used together with --cpu-period or --cpu-quota")
+	}
+
+	const period = 100000
+	quota := int64(cc.cpus * period)
+	if quota < 1000 {
+		return 0, 0, fmt.Errorf("invalid value for --cpus: %v, resulting cpu-quota %dus is below the 1000us minimum", cc.cpus, quota)
+	}
+
+	return period, quota, nil
+}
+
+// validateHealthFlags checks the --health-* flags for consistency:
+// --no-healthcheck is mutually exclusive with the other health flags, and
+// any configured interval, timeout or start period must be at least a second.
+func (cc *CreateCommand) validateHealthFlags() error {
+	healthFlagsSet := cc.healthCmd != "" || cc.healthInterval != 0 || cc.healthTimeout != 0 || cc.healthStartPeriod != 0 || cc.healthRetries != 0
+
+	if cc.noHealthcheck {
+		if healthFlagsSet {
+			return fmt.Errorf("--no-healthcheck cannot be used together with other --health-* flags")
+		}
+		return nil
+	}
+
+	for name, d := range map[string]time.Duration{
+		"--health-interval":     cc.healthInterval,
+		"--health-timeout":      cc.healthTimeout,
+		"--health-start-period": cc.healthStartPeriod,
+	} {
+		if d != 0 && d < time.Second {
+			return fmt.Errorf("invalid value for %s: %s, must be at least 1s", name, d)
+		}
+	}
+	return nil
+}
+
+// validateNetworkFlags validates --dns, --dns-search and --add-host: DNS
+// entries must parse as IP addresses, search domains must be valid RFC-1123
+// labels, and --add-host splits on the first colon (a hostname cannot
+// itself contain one) so that IPv6 addresses in the remainder are
+// tolerated.
+func (cc *CreateCommand) validateNetworkFlags() error {
+	for _, dns := range cc.dns {
+		if net.ParseIP(dns) == nil {
+			return fmt.Errorf("invalid value for --dns: %s is not an IP address", dns)
+		}
+	}
+
+	for _, search := range cc.dnsSearch {
+		if !isValidDNSSearch(search) {
+			return fmt.Errorf("invalid value for --dns-search: %s is not a valid domain", search)
+		}
+	}
+
+	for _, host := range cc.extraHosts {
+		idx := strings.Index(host, ":")
+		if idx <= 0 || idx == len(host)-1 {
+			return fmt.Errorf("invalid value for --add-host: %s must be of the form host:ip", host)
+		}
+		if net.ParseIP(host[idx+1:]) == nil {
+			return fmt.Errorf("invalid value for --add-host: %s is not an IP address", host[idx+1:])
+		}
+	}
+
+	return nil
+}
+
+// isValidDNSSearch reports whether domain is a valid RFC-1123 DNS search
+// domain: dot-separated labels of letters, digits and hyphens that don't
+// start or end with a hyphen. The empty string and a lone "." are also
+// accepted.
+func isValidDNSSearch(domain string) bool {
+	if domain == "" || domain == "." {
+		return true
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(domain, "."), ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // createExample shows examples in create command, and is used in auto-generated cli docs.
 func createExample() string {
 	return `$ pouch create --name foo busybox:latest
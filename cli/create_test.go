@@ -0,0 +1,175 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsMemoryUnbounded(t *testing.T) {
+	cases := []struct {
+		name   string
+		memory string
+		want   bool
+	}{
+		{name: "unset", memory: "", want: true},
+		{name: "explicit zero", memory: "0", want: true},
+		{name: "explicit zero with unit", memory: "0m", want: true},
+		{name: "bounded", memory: "512m", want: false},
+		{name: "unparseable falls back to bounded", memory: "bogus", want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isMemoryUnbounded(c.memory); got != c.want {
+				t.Fatalf("isMemoryUnbounded(%q) = %v, want %v", c.memory, got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateHealthFlags(t *testing.T) {
+	cases := []struct {
+		name    string
+		cc      *CreateCommand
+		wantErr bool
+	}{
+		{name: "nothing set", cc: &CreateCommand{}},
+		{name: "cmd only", cc: &CreateCommand{healthCmd: "curl -f http://localhost"}},
+		{name: "no-healthcheck alone", cc: &CreateCommand{noHealthcheck: true}},
+		{
+			name:    "no-healthcheck with cmd conflicts",
+			cc:      &CreateCommand{noHealthcheck: true, healthCmd: "curl -f http://localhost"},
+			wantErr: true,
+		},
+		{
+			name:    "no-healthcheck with interval conflicts",
+			cc:      &CreateCommand{noHealthcheck: true, healthInterval: time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "interval below 1s",
+			cc:      &CreateCommand{healthInterval: 500 * time.Millisecond},
+			wantErr: true,
+		},
+		{
+			name:    "timeout below 1s",
+			cc:      &CreateCommand{healthTimeout: 500 * time.Millisecond},
+			wantErr: true,
+		},
+		{
+			name:    "start period below 1s",
+			cc:      &CreateCommand{healthStartPeriod: 500 * time.Millisecond},
+			wantErr: true,
+		},
+		{name: "interval at 1s", cc: &CreateCommand{healthInterval: time.Second}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.cc.validateHealthFlags()
+			if c.wantErr && err == nil {
+				t.Fatalf("validateHealthFlags() = nil error, want error")
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("validateHealthFlags() returned unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateNetworkFlags(t *testing.T) {
+	cases := []struct {
+		name    string
+		cc      *CreateCommand
+		wantErr bool
+	}{
+		{name: "nothing set", cc: &CreateCommand{}},
+		{name: "valid dns", cc: &CreateCommand{dns: []string{"8.8.8.8"}}},
+		{name: "invalid dns", cc: &CreateCommand{dns: []string{"not-an-ip"}}, wantErr: true},
+		{name: "valid dns search", cc: &CreateCommand{dnsSearch: []string{"example.com"}}},
+		{name: "invalid dns search", cc: &CreateCommand{dnsSearch: []string{"-bad.com"}}, wantErr: true},
+		{name: "valid add-host", cc: &CreateCommand{extraHosts: []string{"foo:10.0.0.1"}}},
+		{name: "valid add-host ipv6", cc: &CreateCommand{extraHosts: []string{"foo:::1"}}},
+		{name: "add-host missing colon", cc: &CreateCommand{extraHosts: []string{"foo"}}, wantErr: true},
+		{name: "add-host empty host", cc: &CreateCommand{extraHosts: []string{":10.0.0.1"}}, wantErr: true},
+		{name: "add-host invalid ip", cc: &CreateCommand{extraHosts: []string{"foo:bar"}}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.cc.validateNetworkFlags()
+			if c.wantErr && err == nil {
+				t.Fatalf("validateNetworkFlags() = nil error, want error")
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("validateNetworkFlags() returned unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestIsValidDNSSearch(t *testing.T) {
+	cases := []struct {
+		name   string
+		domain string
+		want   bool
+	}{
+		{name: "empty", domain: "", want: true},
+		{name: "dot only", domain: ".", want: true},
+		{name: "simple domain", domain: "example.com", want: true},
+		{name: "trailing dot", domain: "example.com.", want: true},
+		{name: "multi-label", domain: "my-search.example.com", want: true},
+		{name: "empty label", domain: "example..com", want: false},
+		{name: "leading hyphen", domain: "-example.com", want: false},
+		{name: "trailing hyphen", domain: "example-.com", want: false},
+		{name: "invalid character", domain: "exa_mple.com", want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isValidDNSSearch(c.domain); got != c.want {
+				t.Fatalf("isValidDNSSearch(%q) = %v, want %v", c.domain, got, c.want)
+			}
+		})
+	}
+}
+
+func TestResolveCPUQuota(t *testing.T) {
+	cases := []struct {
+		name       string
+		cpus       float64
+		cpuPeriod  int64
+		cpuQuota   int64
+		wantPeriod int64
+		wantQuota  int64
+		wantErr    bool
+	}{
+		{name: "no flags set", wantPeriod: 0, wantQuota: 0},
+		{name: "cpus translated", cpus: 1.5, wantPeriod: 100000, wantQuota: 150000},
+		{name: "explicit period and quota passthrough", cpuPeriod: 50000, cpuQuota: 25000, wantPeriod: 50000, wantQuota: 25000},
+		{name: "negative cpus rejected", cpus: -1, wantErr: true},
+		{name: "cpus conflicts with cpu-period", cpus: 1, cpuPeriod: 100000, wantErr: true},
+		{name: "cpus conflicts with cpu-quota", cpus: 1, cpuQuota: 50000, wantErr: true},
+		{name: "cpus below 1000us minimum", cpus: 0.005, wantErr: true},
+		{name: "explicit cpu-quota below 1000us minimum", cpuQuota: 500, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cc := &CreateCommand{cpus: c.cpus, cpuPeriod: c.cpuPeriod, cpuQuota: c.cpuQuota}
+			period, quota, err := cc.resolveCPUQuota()
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("resolveCPUQuota() = nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveCPUQuota() returned unexpected error: %v", err)
+			}
+			if period != c.wantPeriod || quota != c.wantQuota {
+				t.Fatalf("resolveCPUQuota() = (%d, %d), want (%d, %d)", period, quota, c.wantPeriod, c.wantQuota)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envVarNameRegexp matches a valid environment variable name.
+var envVarNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// parseEnvFile reads a --env-file. Blank lines and lines starting with '#'
+// are skipped. A bare KEY inherits its value from the pouch client's own
+// environment, KEY= sets an empty value, and KEY=VALUE sets VALUE. A UTF-8
+// BOM at the start of the file is stripped.
+func parseEnvFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open env file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\xEF\xBB\xBF")
+			first = false
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key := line
+		if idx := strings.Index(line, "="); idx != -1 {
+			key = line[:idx]
+		}
+		if !envVarNameRegexp.MatchString(key) {
+			return nil, fmt.Errorf("invalid environment variable name %q in %s", key, path)
+		}
+
+		if !strings.Contains(line, "=") {
+			if value, ok := os.LookupEnv(key); ok {
+				line = fmt.Sprintf("%s=%s", key, value)
+			} else {
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file %s: %v", path, err)
+	}
+
+	return lines, nil
+}
+
+// mergeEnvFiles merges the KEY=VALUE entries parsed from envFiles (later
+// files override earlier ones), then applies env on top so that explicit
+// --env entries always take precedence over --env-file entries.
+func mergeEnvFiles(envFiles, env []string) ([]string, error) {
+	order := make([]string, 0, len(env))
+	merged := make(map[string]string)
+
+	set := func(kv string) {
+		idx := strings.Index(kv, "=")
+		key := kv[:idx]
+		if _, ok := merged[key]; !ok {
+			order = append(order, key)
+		}
+		merged[key] = kv[idx+1:]
+	}
+
+	for _, path := range envFiles {
+		lines, err := parseEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range lines {
+			set(kv)
+		}
+	}
+	for _, kv := range env {
+		if !strings.Contains(kv, "=") {
+			value, ok := os.LookupEnv(kv)
+			if !ok {
+				continue
+			}
+			kv = fmt.Sprintf("%s=%s", kv, value)
+		}
+		set(kv)
+	}
+
+	result := make([]string, 0, len(order))
+	for _, key := range order {
+		result = append(result, fmt.Sprintf("%s=%s", key, merged[key]))
+	}
+	return result, nil
+}
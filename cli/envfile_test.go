@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "env")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write test env file: %v", err)
+	}
+	return path
+}
+
+func TestParseEnvFile(t *testing.T) {
+	t.Setenv("POUCH_TEST_ENVFILE_VAR", "from-environment")
+
+	path := writeEnvFile(t, "# a comment\n\nFOO=bar\nEMPTY=\nPOUCH_TEST_ENVFILE_VAR\n")
+	got, err := parseEnvFile(path)
+	if err != nil {
+		t.Fatalf("parseEnvFile returned unexpected error: %v", err)
+	}
+
+	want := []string{"FOO=bar", "EMPTY=", "POUCH_TEST_ENVFILE_VAR=from-environment"}
+	if len(got) != len(want) {
+		t.Fatalf("parseEnvFile = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("parseEnvFile = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestParseEnvFileStripsBOM(t *testing.T) {
+	path := writeEnvFile(t, "\xEF\xBB\xBFFOO=bar\n")
+	got, err := parseEnvFile(path)
+	if err != nil {
+		t.Fatalf("parseEnvFile returned unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "FOO=bar" {
+		t.Fatalf("parseEnvFile = %v, want [FOO=bar]", got)
+	}
+}
+
+func TestParseEnvFileBareUnsetVarSkipped(t *testing.T) {
+	os.Unsetenv("POUCH_TEST_ENVFILE_UNSET_VAR")
+	path := writeEnvFile(t, "POUCH_TEST_ENVFILE_UNSET_VAR\n")
+	got, err := parseEnvFile(path)
+	if err != nil {
+		t.Fatalf("parseEnvFile returned unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("parseEnvFile = %v, want empty", got)
+	}
+}
+
+func TestParseEnvFileInvalidName(t *testing.T) {
+	path := writeEnvFile(t, "1INVALID=bar\n")
+	if _, err := parseEnvFile(path); err == nil {
+		t.Fatal("parseEnvFile with an invalid variable name returned nil error, want error")
+	}
+}
+
+func TestMergeEnvFilesPrecedence(t *testing.T) {
+	path := writeEnvFile(t, "FOO=from-file\nBAR=from-file\n")
+
+	got, err := mergeEnvFiles([]string{path}, []string{"FOO=from-flag"})
+	if err != nil {
+		t.Fatalf("mergeEnvFiles returned unexpected error: %v", err)
+	}
+
+	want := map[string]string{"FOO": "from-flag", "BAR": "from-file"}
+	if len(got) != len(want) {
+		t.Fatalf("mergeEnvFiles = %v, want entries for %v", got, want)
+	}
+	for _, kv := range got {
+		idx := len(kv)
+		for i, c := range kv {
+			if c == '=' {
+				idx = i
+				break
+			}
+		}
+		key, value := kv[:idx], kv[idx+1:]
+		if want[key] != value {
+			t.Fatalf("mergeEnvFiles entry %q, want value %q for key %q", kv, want[key], key)
+		}
+	}
+}
+
+func TestMergeEnvFilesLaterFileWins(t *testing.T) {
+	first := writeEnvFile(t, "FOO=one\n")
+	second := writeEnvFile(t, "FOO=two\n")
+
+	got, err := mergeEnvFiles([]string{first, second}, nil)
+	if err != nil {
+		t.Fatalf("mergeEnvFiles returned unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "FOO=two" {
+		t.Fatalf("mergeEnvFiles = %v, want [FOO=two]", got)
+	}
+}
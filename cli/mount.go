@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-units"
+)
+
+// mountEntry is a single entry parsed from --mount, before it is converted
+// into the API's mount.Mount for HostConfig.Mounts.
+type mountEntry struct {
+	Type            string
+	Source          string
+	Target          string
+	ReadOnly        bool
+	BindPropagation string
+	VolumeDriver    string
+	VolumeOpts      map[string]string
+	TmpfsSize       string
+	TmpfsMode       string
+	Consistency     string
+}
+
+// mountOpt is a repeatable pflag.Value implementing
+// --mount type=bind|volume|tmpfs,source=...,target=...,... .
+type mountOpt struct {
+	values []*mountEntry
+}
+
+// String implements pflag.Value.
+func (o *mountOpt) String() string {
+	s := make([]string, 0, len(o.values))
+	for _, m := range o.values {
+		s = append(s, fmt.Sprintf("%s:%s", m.Type, m.Target))
+	}
+	return strings.Join(s, ", ")
+}
+
+// Set implements pflag.Value, parsing a single comma-separated key=value
+// --mount entry.
+func (o *mountOpt) Set(val string) error {
+	m := &mountEntry{Type: "volume"}
+
+	for _, field := range strings.Split(val, ",") {
+		if field == "" {
+			continue
+		}
+
+		kv := strings.SplitN(field, "=", 2)
+		key := kv[0]
+		var value string
+		if len(kv) == 2 {
+			value = kv[1]
+		}
+
+		switch key {
+		case "type":
+			m.Type = value
+		case "source", "src":
+			m.Source = value
+		case "target", "dst", "destination":
+			m.Target = value
+		case "readonly", "ro":
+			m.ReadOnly = true
+		case "bind-propagation":
+			m.BindPropagation = value
+		case "volume-driver":
+			m.VolumeDriver = value
+		case "volume-opt":
+			optKV := strings.SplitN(value, "=", 2)
+			if len(optKV) != 2 {
+				return fmt.Errorf("invalid volume-opt %q: must be key=value", value)
+			}
+			if m.VolumeOpts == nil {
+				m.VolumeOpts = make(map[string]string)
+			}
+			m.VolumeOpts[optKV[0]] = optKV[1]
+		case "tmpfs-size":
+			m.TmpfsSize = value
+		case "tmpfs-mode":
+			m.TmpfsMode = value
+		case "consistency":
+			m.Consistency = value
+		default:
+			return fmt.Errorf("invalid mount field %q", key)
+		}
+	}
+
+	if err := validateMountEntry(m); err != nil {
+		return err
+	}
+
+	o.values = append(o.values, m)
+	return nil
+}
+
+// Type implements pflag.Value.
+func (o *mountOpt) Type() string {
+	return "mount"
+}
+
+func validateMountEntry(m *mountEntry) error {
+	switch m.Type {
+	case "bind", "volume", "tmpfs":
+	default:
+		return fmt.Errorf("invalid mount type %q: must be bind, volume or tmpfs", m.Type)
+	}
+	if m.Target == "" {
+		return fmt.Errorf("target is required for a mount")
+	}
+	if m.Type == "tmpfs" && m.Source != "" {
+		return fmt.Errorf("source is not supported for tmpfs mounts")
+	}
+	if m.Type == "bind" && m.Source == "" {
+		return fmt.Errorf("source is required for a bind mount")
+	}
+	return nil
+}
+
+// toAPIMount converts a parsed --mount entry into the API's mount.Mount.
+func (m *mountEntry) toAPIMount() (mount.Mount, error) {
+	apiMount := mount.Mount{
+		Type:        mount.Type(m.Type),
+		Source:      m.Source,
+		Target:      m.Target,
+		ReadOnly:    m.ReadOnly,
+		Consistency: mount.Consistency(m.Consistency),
+	}
+
+	if m.Type == "bind" && m.BindPropagation != "" {
+		apiMount.BindOptions = &mount.BindOptions{Propagation: mount.Propagation(m.BindPropagation)}
+	}
+
+	if m.Type == "volume" && (m.VolumeDriver != "" || len(m.VolumeOpts) > 0) {
+		apiMount.VolumeOptions = &mount.VolumeOptions{}
+		if m.VolumeDriver != "" || len(m.VolumeOpts) > 0 {
+			apiMount.VolumeOptions.DriverConfig = &mount.Driver{Name: m.VolumeDriver, Options: m.VolumeOpts}
+		}
+	}
+
+	if m.Type == "tmpfs" && (m.TmpfsSize != "" || m.TmpfsMode != "") {
+		tmpfsOpts := &mount.TmpfsOptions{}
+		if m.TmpfsSize != "" {
+			size, err := units.RAMInBytes(m.TmpfsSize)
+			if err != nil {
+				return mount.Mount{}, fmt.Errorf("invalid tmpfs-size %q: %v", m.TmpfsSize, err)
+			}
+			tmpfsOpts.SizeBytes = size
+		}
+		if m.TmpfsMode != "" {
+			mode, err := strconv.ParseUint(m.TmpfsMode, 8, 32)
+			if err != nil {
+				return mount.Mount{}, fmt.Errorf("invalid tmpfs-mode %q: %v", m.TmpfsMode, err)
+			}
+			tmpfsOpts.Mode = os.FileMode(mode)
+		}
+		apiMount.TmpfsOptions = tmpfsOpts
+	}
+
+	return apiMount, nil
+}
+
+// volumeTargets extracts the destination path of each legacy --volume entry
+// (source:target[:options] or target), for duplicate-target checking
+// against --mount. cc.config() is responsible for turning --volume into
+// HostConfig.Binds; these targets are never themselves written to
+// HostConfig.Mounts.
+func volumeTargets(volumes []string) ([]string, error) {
+	targets := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		parts := strings.Split(v, ":")
+		switch len(parts) {
+		case 1:
+			targets = append(targets, parts[0])
+		case 2, 3:
+			targets = append(targets, parts[1])
+		default:
+			return nil, fmt.Errorf("invalid volume %q", v)
+		}
+	}
+	return targets, nil
+}
+
+// validateMountTargets rejects duplicate targets across --volume and
+// --mount.
+func validateMountTargets(volumes []string, mounts []*mountEntry) error {
+	seen := make(map[string]bool, len(mounts))
+	for _, m := range mounts {
+		if seen[m.Target] {
+			return fmt.Errorf("duplicate mount target %q", m.Target)
+		}
+		seen[m.Target] = true
+	}
+
+	targets, err := volumeTargets(volumes)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if seen[t] {
+			return fmt.Errorf("duplicate mount target %q", t)
+		}
+		seen[t] = true
+	}
+
+	return nil
+}
+
+// toAPIMounts converts every parsed --mount entry into the API's
+// mount.Mount, for HostConfig.Mounts. --volume entries are left for
+// cc.config() to translate into HostConfig.Binds.
+func toAPIMounts(mounts []*mountEntry) ([]mount.Mount, error) {
+	apiMounts := make([]mount.Mount, 0, len(mounts))
+	for _, m := range mounts {
+		apiMount, err := m.toAPIMount()
+		if err != nil {
+			return nil, err
+		}
+		apiMounts = append(apiMounts, apiMount)
+	}
+	return apiMounts, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import "testing"
+
+func TestMountOptSet(t *testing.T) {
+	cases := []struct {
+		name    string
+		val     string
+		want    *mountEntry
+		wantErr bool
+	}{
+		{
+			name: "defaults to volume type",
+			val:  "target=/data",
+			want: &mountEntry{Type: "volume", Target: "/data"},
+		},
+		{
+			name: "bind with propagation",
+			val:  "type=bind,source=/host,target=/data,bind-propagation=shared",
+			want: &mountEntry{Type: "bind", Source: "/host", Target: "/data", BindPropagation: "shared"},
+		},
+		{
+			name: "readonly flag",
+			val:  "type=bind,source=/host,target=/data,readonly",
+			want: &mountEntry{Type: "bind", Source: "/host", Target: "/data", ReadOnly: true},
+		},
+		{
+			name: "volume with driver and opts",
+			val:  "type=volume,target=/data,volume-driver=local,volume-opt=o=bind",
+			want: &mountEntry{Type: "volume", Target: "/data", VolumeDriver: "local", VolumeOpts: map[string]string{"o": "bind"}},
+		},
+		{
+			name: "tmpfs with size and mode",
+			val:  "type=tmpfs,target=/cache,tmpfs-size=64m,tmpfs-mode=1700",
+			want: &mountEntry{Type: "tmpfs", Target: "/cache", TmpfsSize: "64m", TmpfsMode: "1700"},
+		},
+		{
+			name:    "invalid type",
+			val:     "type=bogus,target=/data",
+			wantErr: true,
+		},
+		{
+			name:    "missing target",
+			val:     "type=bind,source=/host",
+			wantErr: true,
+		},
+		{
+			name:    "tmpfs with source",
+			val:     "type=tmpfs,source=/host,target=/data",
+			wantErr: true,
+		},
+		{
+			name:    "bind without source",
+			val:     "type=bind,target=/data",
+			wantErr: true,
+		},
+		{
+			name:    "invalid volume-opt",
+			val:     "target=/data,volume-opt=bogus",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			val:     "bogus=1,target=/data",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var o mountOpt
+			err := o.Set(c.val)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("Set(%q) = nil error, want error", c.val)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set(%q) returned unexpected error: %v", c.val, err)
+			}
+			got := o.values[0]
+			if got.Type != c.want.Type || got.Source != c.want.Source || got.Target != c.want.Target ||
+				got.ReadOnly != c.want.ReadOnly || got.BindPropagation != c.want.BindPropagation ||
+				got.VolumeDriver != c.want.VolumeDriver || got.TmpfsSize != c.want.TmpfsSize || got.TmpfsMode != c.want.TmpfsMode {
+				t.Fatalf("Set(%q) parsed %+v, want %+v", c.val, got, c.want)
+			}
+		})
+	}
+}
+
+func TestVolumeTargets(t *testing.T) {
+	cases := []struct {
+		name    string
+		volumes []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "target only", volumes: []string{"/data"}, want: []string{"/data"}},
+		{name: "source and target", volumes: []string{"/host:/data"}, want: []string{"/data"}},
+		{name: "source target and options", volumes: []string{"/host:/data:ro"}, want: []string{"/data"}},
+		{name: "too many fields", volumes: []string{"a:b:c:d"}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := volumeTargets(c.volumes)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("volumeTargets(%v) = nil error, want error", c.volumes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("volumeTargets(%v) returned unexpected error: %v", c.volumes, err)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("volumeTargets(%v) = %v, want %v", c.volumes, got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("volumeTargets(%v) = %v, want %v", c.volumes, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMountTargetsRejectsDuplicates(t *testing.T) {
+	mounts := []*mountEntry{{Type: "volume", Target: "/data"}}
+
+	if err := validateMountTargets([]string{"/other"}, mounts); err != nil {
+		t.Fatalf("validateMountTargets returned unexpected error: %v", err)
+	}
+	if err := validateMountTargets([]string{"/data"}, mounts); err == nil {
+		t.Fatal("validateMountTargets with a duplicate --volume target returned nil error, want error")
+	}
+
+	duplicateMounts := []*mountEntry{{Type: "volume", Target: "/data"}, {Type: "volume", Target: "/data"}}
+	if err := validateMountTargets(nil, duplicateMounts); err == nil {
+		t.Fatal("validateMountTargets with duplicate --mount targets returned nil error, want error")
+	}
+}
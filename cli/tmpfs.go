@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// tmpfsValueOptions is the set of bare (non key=value) tmpfs mount options
+// pouch create recognises via --tmpfs.
+var tmpfsValueOptions = map[string]bool{
+	"rw":      true,
+	"ro":      true,
+	"nosuid":  true,
+	"nodev":   true,
+	"noexec":  true,
+	"noatime": true,
+}
+
+// tmpfsKeyOptions is the set of key=value tmpfs mount options pouch create
+// recognises via --tmpfs.
+var tmpfsKeyOptions = map[string]bool{
+	"size": true,
+	"mode": true,
+	"uid":  true,
+	"gid":  true,
+}
+
+// tmpfsOpt is a repeatable pflag.Value implementing
+// --tmpfs /path[:opt1,opt2,...], collecting the destination path to its
+// comma-joined option string.
+type tmpfsOpt struct {
+	values map[string]string
+}
+
+// String implements pflag.Value.
+func (o *tmpfsOpt) String() string {
+	var s []string
+	for dest, opts := range o.values {
+		if opts == "" {
+			s = append(s, dest)
+			continue
+		}
+		s = append(s, fmt.Sprintf("%s:%s", dest, opts))
+	}
+	return strings.Join(s, ", ")
+}
+
+// Set implements pflag.Value, parsing a single "/path[:opt1,opt2,...]" entry.
+func (o *tmpfsOpt) Set(val string) error {
+	parts := strings.SplitN(val, ":", 2)
+	dest := parts[0]
+	if !filepath.IsAbs(dest) {
+		return fmt.Errorf("invalid tmpfs destination %q: must be an absolute path", dest)
+	}
+
+	var opts string
+	if len(parts) == 2 {
+		opts = parts[1]
+		if err := validateTmpfsOptions(opts); err != nil {
+			return fmt.Errorf("invalid tmpfs options for %s: %v", dest, err)
+		}
+	}
+
+	if o.values == nil {
+		o.values = make(map[string]string)
+	}
+	o.values[dest] = opts
+	return nil
+}
+
+// Type implements pflag.Value.
+func (o *tmpfsOpt) Type() string {
+	return "tmpfs"
+}
+
+func validateTmpfsOptions(opts string) error {
+	var sawRW, sawRO bool
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "" {
+			continue
+		}
+
+		if kv := strings.SplitN(opt, "=", 2); len(kv) == 2 {
+			if !tmpfsKeyOptions[kv[0]] {
+				return fmt.Errorf("unrecognised tmpfs option %q", opt)
+			}
+			continue
+		}
+
+		if !tmpfsValueOptions[opt] {
+			return fmt.Errorf("unrecognised tmpfs option %q", opt)
+		}
+		switch opt {
+		case "rw":
+			sawRW = true
+		case "ro":
+			sawRO = true
+		}
+	}
+
+	if sawRW && sawRO {
+		return fmt.Errorf("options 'rw' and 'ro' are mutually exclusive")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestValidateTmpfsOptions(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    string
+		wantErr bool
+	}{
+		{name: "empty", opts: ""},
+		{name: "single flag", opts: "noexec"},
+		{name: "multiple flags", opts: "noexec,nosuid,nodev"},
+		{name: "key value", opts: "size=64m,mode=1777"},
+		{name: "flags and key value", opts: "rw,size=64m"},
+		{name: "unrecognised flag", opts: "bogus", wantErr: true},
+		{name: "unrecognised key", opts: "bogus=1", wantErr: true},
+		{name: "rw and ro conflict", opts: "rw,ro", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateTmpfsOptions(c.opts)
+			if c.wantErr && err == nil {
+				t.Fatalf("validateTmpfsOptions(%q) = nil error, want error", c.opts)
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("validateTmpfsOptions(%q) returned unexpected error: %v", c.opts, err)
+			}
+		})
+	}
+}
+
+func TestTmpfsOptSetRejectsRelativePath(t *testing.T) {
+	var o tmpfsOpt
+	if err := o.Set("relative/path"); err == nil {
+		t.Fatal("Set with a relative destination returned nil error, want error")
+	}
+}
+
+func TestTmpfsOptSetStoresOptions(t *testing.T) {
+	var o tmpfsOpt
+	if err := o.Set("/tmp/cache:noexec,size=64m"); err != nil {
+		t.Fatalf("Set returned unexpected error: %v", err)
+	}
+	if got, want := o.values["/tmp/cache"], "noexec,size=64m"; got != want {
+		t.Fatalf("o.values[\"/tmp/cache\"] = %q, want %q", got, want)
+	}
+}
+
+func TestTmpfsOptSetInvalidOptionsNotStored(t *testing.T) {
+	var o tmpfsOpt
+	if err := o.Set("/tmp/cache:bogus"); err == nil {
+		t.Fatal("Set with invalid options returned nil error, want error")
+	}
+	if _, ok := o.values["/tmp/cache"]; ok {
+		t.Fatal("invalid entry was stored in o.values")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// ulimitWhitelist is the set of rlimit names pouch create accepts via
+// --ulimit. This mirrors github.com/docker/go-units's Ulimit.GetRlimit,
+// which is what turns these into OCI process.rlimits; "as" is deliberately
+// excluded because that library disables it (doesn't work with container
+// init).
+var ulimitWhitelist = map[string]bool{
+	"nofile":     true,
+	"nproc":      true,
+	"core":       true,
+	"memlock":    true,
+	"stack":      true,
+	"rss":        true,
+	"cpu":        true,
+	"data":       true,
+	"fsize":      true,
+	"sigpending": true,
+	"msgqueue":   true,
+	"nice":       true,
+	"rtprio":     true,
+	"rttime":     true,
+	"locks":      true,
+}
+
+// ulimitOpt is a repeatable pflag.Value implementing --ulimit name=soft[:hard],
+// collecting entries as the units.Ulimit type pouchd forwards into the OCI
+// spec's process.rlimits. Duplicate ulimit names are rejected.
+type ulimitOpt struct {
+	values []*units.Ulimit
+}
+
+// String implements pflag.Value.
+func (o *ulimitOpt) String() string {
+	s := make([]string, 0, len(o.values))
+	for _, u := range o.values {
+		s = append(s, u.String())
+	}
+	return strings.Join(s, ", ")
+}
+
+// Set implements pflag.Value, parsing a single "name=soft[:hard]" entry.
+func (o *ulimitOpt) Set(val string) error {
+	u, err := parseUlimit(val)
+	if err != nil {
+		return err
+	}
+	for _, existing := range o.values {
+		if existing.Name == u.Name {
+			return fmt.Errorf("ulimit %q has already been set", u.Name)
+		}
+	}
+	o.values = append(o.values, u)
+	return nil
+}
+
+// Type implements pflag.Value.
+func (o *ulimitOpt) Type() string {
+	return "ulimit"
+}
+
+// parseUlimit parses a single "name=soft[:hard]" entry, as accepted by
+// --ulimit, into a units.Ulimit. A soft or hard value of -1 or the literal
+// "unlimited" means unlimited.
+func parseUlimit(val string) (*units.Ulimit, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid ulimit argument: %s", val)
+	}
+
+	name := parts[0]
+	if !ulimitWhitelist[name] {
+		return nil, fmt.Errorf("invalid ulimit name %q: must be one of %s", name, strings.Join(ulimitNames(), ", "))
+	}
+
+	limitVals := strings.SplitN(parts[1], ":", 2)
+	soft, err := parseUlimitValue(limitVals[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ulimit argument: %s: %v", val, err)
+	}
+
+	hard := soft
+	if len(limitVals) == 2 {
+		if hard, err = parseUlimitValue(limitVals[1]); err != nil {
+			return nil, fmt.Errorf("invalid ulimit argument: %s: %v", val, err)
+		}
+	}
+	if soft != -1 && hard != -1 && soft > hard {
+		return nil, fmt.Errorf("invalid ulimit argument: %s: soft limit must be less than or equal to hard limit", val)
+	}
+
+	return &units.Ulimit{Name: name, Soft: soft, Hard: hard}, nil
+}
+
+func parseUlimitValue(s string) (int64, error) {
+	if s == "unlimited" {
+		return -1, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func ulimitNames() []string {
+	names := make([]string, 0, len(ulimitWhitelist))
+	for name := range ulimitWhitelist {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestParseUlimit(t *testing.T) {
+	cases := []struct {
+		name    string
+		val     string
+		soft    int64
+		hard    int64
+		wantErr bool
+	}{
+		{name: "soft only", val: "nofile=1024", soft: 1024, hard: 1024},
+		{name: "soft and hard", val: "nofile=1024:2048", soft: 1024, hard: 2048},
+		{name: "unlimited soft and hard", val: "nofile=unlimited:unlimited", soft: -1, hard: -1},
+		{name: "unlimited via -1", val: "nofile=-1:-1", soft: -1, hard: -1},
+		{name: "missing equals", val: "nofile", wantErr: true},
+		{name: "empty name", val: "=1024", wantErr: true},
+		{name: "not whitelisted", val: "bogus=1024", wantErr: true},
+		{name: "non-numeric value", val: "nofile=abc", wantErr: true},
+		{name: "soft greater than hard", val: "nofile=2048:1024", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			u, err := parseUlimit(c.val)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("parseUlimit(%q) = nil error, want error", c.val)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseUlimit(%q) returned unexpected error: %v", c.val, err)
+			}
+			if u.Soft != c.soft || u.Hard != c.hard {
+				t.Fatalf("parseUlimit(%q) = {Soft: %d, Hard: %d}, want {Soft: %d, Hard: %d}", c.val, u.Soft, u.Hard, c.soft, c.hard)
+			}
+		})
+	}
+}
+
+func TestUlimitOptSetRejectsDuplicates(t *testing.T) {
+	var o ulimitOpt
+	if err := o.Set("nofile=1024"); err != nil {
+		t.Fatalf("first Set returned unexpected error: %v", err)
+	}
+	if err := o.Set("nofile=2048"); err == nil {
+		t.Fatal("second Set with duplicate name returned nil error, want error")
+	}
+	if len(o.values) != 1 {
+		t.Fatalf("len(o.values) = %d, want 1", len(o.values))
+	}
+}